@@ -0,0 +1,73 @@
+// Package config processes the CLI flags that control how the acceptance suite
+// provisions and targets Kubernetes clusters, and exposes them to tests as a
+// TestConfig.
+package config
+
+import "flag"
+
+// TestFlags defines the CLI flags accepted by the acceptance test binary and
+// turns them into a TestConfig once parsing is done.
+type TestFlags struct {
+	flagEnableEnterprise       bool
+	flagEnableTransparentProxy bool
+	flagUseKind                bool
+	flagUseOpenShift           bool
+	flagNoCleanupOnFailure     bool
+	flagDebugDirectory         string
+}
+
+// Vars registers the acceptance suite's flags on flags, storing their values in
+// t so they can later be turned into a TestConfig via TestConfigFromFlags.
+func (t *TestFlags) Vars(flags *flag.FlagSet) {
+	flags.BoolVar(&t.flagEnableEnterprise, "enable-enterprise", false,
+		"If true, the test suite will run tests for enterprise features. "+
+			"Note that some features may require setting the enterprise license flag below or the env var CONSUL_ENT_LICENSE")
+	flags.BoolVar(&t.flagEnableTransparentProxy, "enable-transparent-proxy", false,
+		"If true, the test suite will run tests that require transparent proxy.")
+	flags.BoolVar(&t.flagUseKind, "use-kind", false,
+		"If true, the tests will assume they're running against a local Kind cluster(s) "+
+			"and set NodePort services to use ports 30000-32767 and will not use LoadBalancer services.")
+	flags.BoolVar(&t.flagUseOpenShift, "use-openshift", false,
+		"If true, the tests will assume they're running against OpenShift clusters and will "+
+			"configure Helm installs, namespace creation, and service exposure accordingly "+
+			"(SCCs, Routes instead of NodePorts, etc).")
+	flags.BoolVar(&t.flagNoCleanupOnFailure, "no-cleanup-on-failure", false,
+		"If true, the tests will not cleanup Kubernetes resources they create when they finish running.")
+	flags.StringVar(&t.flagDebugDirectory, "debug-directory", "",
+		"The directory where to write debug information about failed test runs, "+
+			"such as logs and pod definitions.")
+}
+
+// TestConfigFromFlags returns a TestConfig populated from the parsed flags.
+func (t *TestFlags) TestConfigFromFlags() *TestConfig {
+	return &TestConfig{
+		EnableEnterprise:       t.flagEnableEnterprise,
+		EnableTransparentProxy: t.flagEnableTransparentProxy,
+		UseKind:                t.flagUseKind,
+		UseOpenShift:           t.flagUseOpenShift,
+		NoCleanupOnFailure:     t.flagNoCleanupOnFailure,
+		DebugDirectory:         t.flagDebugDirectory,
+	}
+}
+
+// TestConfig holds the configuration that acceptance tests need to know about
+// the clusters they're running against.
+type TestConfig struct {
+	EnableEnterprise       bool
+	EnableTransparentProxy bool
+
+	// UseKind indicates the tests are running against local Kind clusters that
+	// share a docker bridge network, so NodePort services can be used to reach
+	// another cluster's nodes.
+	UseKind bool
+
+	// UseOpenShift indicates the tests are running against OpenShift clusters.
+	// NodePorts aren't reachable across nodes on OCP, so cross-cluster services
+	// need to be exposed via Routes instead, and pods running under OCP's
+	// restricted SCC need their namespaces' service accounts granted the
+	// anyuid/privileged SCCs and their fixtures to drop fixed UIDs.
+	UseOpenShift bool
+
+	NoCleanupOnFailure bool
+	DebugDirectory     string
+}
@@ -0,0 +1,226 @@
+// Package partitions provides a programmatic lifecycle around a Consul admin
+// partition running in its own Kubernetes context, so that acceptance tests
+// exercising multiple partitions don't have to re-implement the CA/ACL token
+// choreography and external-servers wiring by hand.
+package partitions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	terratestk8s "github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/config"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/consul"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/environment"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/helpers"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PartitionCluster is a Consul admin partition installed into a single Kubernetes
+// context, along with enough bookkeeping to join it to the partition hosting the
+// Consul servers (the "default" partition) and to deploy workloads into it.
+type PartitionCluster struct {
+	Name        string
+	ReleaseName string
+
+	Context environment.TestContext
+	Cluster *consul.HelmCluster
+
+	t              *testing.T
+	cfg            *config.TestConfig
+	aclsEnabled    bool
+	caCertSecret   string
+	caKeySecret    string
+	partitionToken string
+}
+
+// NewDefaultPartition installs a Consul cluster with admin partitions enabled into
+// ctx and returns a PartitionCluster representing the "default" partition and its
+// servers. helmValues are merged on top of the admin-partition defaults, so callers
+// can layer on namespace mirroring, ACLs, TLS, etc.
+func NewDefaultPartition(t *testing.T, ctx environment.TestContext, cfg *config.TestConfig, releaseName string, helmValues map[string]string) *PartitionCluster {
+	t.Helper()
+
+	serverHelmValues := map[string]string{
+		"global.adminPartitions.enabled": "true",
+		"server.exposeGossipAndRPCPorts": "true",
+	}
+	helpers.MergeMaps(serverHelmValues, helmValues)
+
+	if cfg.UseKind {
+		serverHelmValues["global.adminPartitions.service.type"] = "NodePort"
+		serverHelmValues["global.adminPartitions.service.nodePort.https"] = "30000"
+	}
+
+	// NodePorts aren't reachable across nodes on OpenShift, so instead we expose
+	// the partition service with a Route (set up in partitionServiceAddress below).
+	if cfg.UseOpenShift {
+		serverHelmValues["global.adminPartitions.service.type"] = "ClusterIP"
+	}
+
+	cluster := consul.NewHelmCluster(t, serverHelmValues, ctx, cfg, releaseName)
+	cluster.Create(t)
+
+	return &PartitionCluster{
+		Name:        "default",
+		ReleaseName: releaseName,
+		Context:     ctx,
+		Cluster:     cluster,
+		t:           t,
+		cfg:         cfg,
+		aclsEnabled: helmValues["global.acls.manageSystemACLs"] == "true",
+	}
+}
+
+// NewSecondaryPartition moves the CA (and, when ACLs are enabled, the partition
+// bootstrap token) secrets from primary's context into ctx, then installs a
+// client-only Consul cluster in ctx joined to primary's servers under admin
+// partition name. helmValues are merged on top of the client defaults.
+func NewSecondaryPartition(t *testing.T, primary *PartitionCluster, ctx environment.TestContext, cfg *config.TestConfig, name string, releaseName string, helmValues map[string]string) *PartitionCluster {
+	t.Helper()
+
+	p := &PartitionCluster{
+		Name:        name,
+		ReleaseName: releaseName,
+		Context:     ctx,
+		t:           t,
+		cfg:         cfg,
+		aclsEnabled: primary.aclsEnabled,
+	}
+
+	p.caCertSecret = fmt.Sprintf("%s-consul-ca-cert", primary.ReleaseName)
+	p.caKeySecret = fmt.Sprintf("%s-consul-ca-key", primary.ReleaseName)
+
+	logger.Logf(t, "retrieving ca cert secret %s from the %s partition and applying to the %s partition", p.caCertSecret, primary.Name, name)
+	moveSecret(t, primary.Context, ctx, p.caCertSecret)
+
+	if !p.aclsEnabled {
+		logger.Logf(t, "retrieving ca key secret %s from the %s partition and applying to the %s partition", p.caKeySecret, primary.Name, name)
+		moveSecret(t, primary.Context, ctx, p.caKeySecret)
+	}
+
+	partitionServiceName := fmt.Sprintf("%s-consul-partition", primary.ReleaseName)
+	partitionSvcAddress := partitionServiceAddress(t, cfg, primary.Context, partitionServiceName)
+	k8sAuthMethodHost := k8s.KubernetesAPIServerHost(t, cfg, ctx)
+
+	clientHelmValues := map[string]string{
+		"global.enabled": "false",
+
+		"global.adminPartitions.name": name,
+
+		"global.tls.caCert.secretName": p.caCertSecret,
+		"global.tls.caCert.secretKey":  "tls.crt",
+
+		"externalServers.enabled":       "true",
+		"externalServers.hosts[0]":      partitionSvcAddress,
+		"externalServers.tlsServerName": "server.dc1.consul",
+
+		"client.enabled":           "true",
+		"client.exposeGossipPorts": "true",
+		"client.join[0]":           partitionSvcAddress,
+	}
+
+	if p.aclsEnabled {
+		p.partitionToken = fmt.Sprintf("%s-consul-partitions-acl-token", primary.ReleaseName)
+		logger.Logf(t, "retrieving partition token secret %s from the %s partition and applying to the %s partition", p.partitionToken, primary.Name, name)
+		moveSecret(t, primary.Context, ctx, p.partitionToken)
+
+		clientHelmValues["global.acls.bootstrapToken.secretName"] = p.partitionToken
+		clientHelmValues["global.acls.bootstrapToken.secretKey"] = "token"
+		clientHelmValues["externalServers.k8sAuthMethodHost"] = k8sAuthMethodHost
+	} else {
+		clientHelmValues["global.tls.caKey.secretName"] = p.caKeySecret
+		clientHelmValues["global.tls.caKey.secretKey"] = "tls.key"
+	}
+
+	if cfg.UseKind {
+		clientHelmValues["externalServers.httpsPort"] = "30000"
+	}
+
+	helpers.MergeMaps(clientHelmValues, helmValues)
+
+	p.Cluster = consul.NewHelmCluster(t, clientHelmValues, ctx, cfg, releaseName)
+	p.Cluster.Create(t)
+
+	agentPodList, err := ctx.KubernetesClient(t).CoreV1().Pods(ctx.KubectlOptions(t).Namespace).List(
+		context.Background(), metav1.ListOptions{LabelSelector: "app=consul,component=client"})
+	require.NoError(t, err)
+	require.NotEmpty(t, agentPodList.Items)
+
+	output, err := k8s.RunKubectlAndGetOutputE(t, ctx.KubectlOptions(t), "logs", agentPodList.Items[0].Name, "-n", ctx.KubectlOptions(t).Namespace)
+	require.NoError(t, err)
+	require.Contains(t, output, fmt.Sprintf("Partition: '%s'", name))
+
+	return p
+}
+
+// ConsulClient returns a Consul API client configured to talk to this partition's
+// servers, along with QueryOptions scoped to partition/namespace.
+func (p *PartitionCluster) ConsulClient(partition, namespace string) (*api.Client, *api.QueryOptions) {
+	client, _ := p.Cluster.SetupConsulClient(p.t, p.aclsEnabled)
+	return client, &api.QueryOptions{Partition: partition, Namespace: namespace}
+}
+
+// ConsulClientForPeer returns a Consul API client configured to talk to this
+// partition's servers, along with QueryOptions scoped to namespace and peer. Use
+// this instead of ConsulClient when reading the catalog for a service imported
+// over a cluster peering connection: imported services keep their original name
+// and are only visible to a query that explicitly asks for that peer.
+func (p *PartitionCluster) ConsulClientForPeer(peer, namespace string) (*api.Client, *api.QueryOptions) {
+	client, _ := p.Cluster.SetupConsulClient(p.t, p.aclsEnabled)
+	return client, &api.QueryOptions{Peer: peer, Namespace: namespace}
+}
+
+// DeployWorkload applies the kustomize overlay at kustomizePath into namespace in
+// this partition's context. The namespace must already exist; callers that need
+// OpenShift SCC grants or other namespace-creation side effects are expected to
+// have created it first.
+func (p *PartitionCluster) DeployWorkload(namespace, kustomizePath string) {
+	p.t.Helper()
+
+	opts := &terratestk8s.KubectlOptions{
+		ContextName: p.Context.KubectlOptions(p.t).ContextName,
+		ConfigPath:  p.Context.KubectlOptions(p.t).ConfigPath,
+		Namespace:   namespace,
+	}
+
+	logger.Logf(p.t, "deploying %s into namespace %s in the %s partition", kustomizePath, namespace, p.Name)
+	k8s.DeployKustomize(p.t, opts, p.cfg.NoCleanupOnFailure, p.cfg.DebugDirectory, kustomizePath)
+}
+
+// partitionServiceAddress returns the address a secondary partition should use to
+// reach primaryContext's partition service. On Kind we rely on a NodePort since all
+// clusters share the docker bridge network; NodePorts aren't reachable across nodes
+// on OpenShift, so there we expose the partition service via a Route instead.
+func partitionServiceAddress(t *testing.T, cfg *config.TestConfig, primaryContext environment.TestContext, partitionServiceName string) string {
+	if !cfg.UseOpenShift {
+		return k8s.ServiceHost(t, cfg, primaryContext, partitionServiceName)
+	}
+
+	routeName := fmt.Sprintf("%s-partition", partitionServiceName)
+	logger.Logf(t, "creating Route %s for partition service %s", routeName, partitionServiceName)
+	k8s.RunKubectl(t, primaryContext.KubectlOptions(t), "create", "route", "passthrough", routeName,
+		fmt.Sprintf("--service=%s", partitionServiceName), "--port=https")
+	helpers.Cleanup(t, cfg.NoCleanupOnFailure, func() {
+		k8s.RunKubectl(t, primaryContext.KubectlOptions(t), "delete", "route", routeName)
+	})
+
+	host, err := k8s.RunKubectlAndGetOutputE(t, primaryContext.KubectlOptions(t), "get", "route", routeName,
+		"-o", "jsonpath={.spec.host}")
+	require.NoError(t, err)
+	require.NotEmpty(t, host)
+	return host
+}
+
+// Cleanup uninstalls the Consul cluster backing this partition. It's registered
+// automatically by consul.NewHelmCluster's t.Cleanup, so callers only need this
+// when they want to tear a partition down early, e.g. before standing up a
+// replacement partition of the same name.
+func (p *PartitionCluster) Cleanup() {
+	p.Cluster.Destroy(p.t)
+}
@@ -0,0 +1,102 @@
+package partitions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/consul-k8s/acceptance/framework/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const peeringAcceptorTemplate = `
+apiVersion: consul.hashicorp.com/v1alpha1
+kind: PeeringAcceptor
+metadata:
+  name: %[1]s
+spec:
+  peer:
+    secret:
+      name: %[1]s-secret
+      key: data
+      backend: kubernetes
+`
+
+const peeringDialerTemplate = `
+apiVersion: consul.hashicorp.com/v1alpha1
+kind: PeeringDialer
+metadata:
+  name: %[1]s
+spec:
+  peer:
+    secret:
+      name: %[2]s
+      key: data
+      backend: kubernetes
+`
+
+// GeneratePeeringToken applies a PeeringAcceptor CRD named peerName in p's partition
+// and waits for the controller to populate the resulting Kubernetes secret, returning
+// its name. peerName is used both as the Consul peer name and the CRD name; the
+// secret is named "<peerName>-secret".
+func (p *PartitionCluster) GeneratePeeringToken(peerName string) string {
+	p.t.Helper()
+
+	applyManifest(p, fmt.Sprintf(peeringAcceptorTemplate, peerName))
+
+	secretName := fmt.Sprintf("%s-secret", peerName)
+	logger.Logf(p.t, "waiting for peering token secret %s in the %s partition", secretName, p.Name)
+	retry.RunWith(&retry.Counter{Count: 30, Wait: 2 * time.Second}, p.t, func(r *retry.R) {
+		_, err := p.Context.KubernetesClient(p.t).CoreV1().Secrets(p.Context.KubectlOptions(p.t).Namespace).Get(
+			context.Background(), secretName, metav1.GetOptions{})
+		require.NoError(r, err)
+	})
+
+	return secretName
+}
+
+// EstablishPeering moves the peering token secret generated by acceptor's
+// GeneratePeeringToken call into p's context, applies a PeeringDialer CRD in p's
+// partition that consumes it, and waits for the Consul peering to become active.
+func (p *PartitionCluster) EstablishPeering(acceptor *PartitionCluster, peerName, secretName string) {
+	p.t.Helper()
+
+	logger.Logf(p.t, "moving peering token secret %s from the %s partition to the %s partition", secretName, acceptor.Name, p.Name)
+	moveSecret(p.t, acceptor.Context, p.Context, secretName)
+
+	applyManifest(p, fmt.Sprintf(peeringDialerTemplate, peerName, secretName))
+
+	logger.Logf(p.t, "waiting for peering %s to become active in the %s partition", peerName, p.Name)
+	client, _ := p.ConsulClient(p.Name, "")
+	retry.RunWith(&retry.Counter{Count: 30, Wait: 2 * time.Second}, p.t, func(r *retry.R) {
+		peering, _, err := client.Peerings().Read(context.Background(), peerName, nil)
+		require.NoError(r, err)
+		require.NotNil(r, peering)
+		require.Equal(r, "PEERING_STATE_ACTIVE", peering.State.String())
+	})
+}
+
+// ApplyManifest kubectl applies the given manifest (e.g. an ExportedServices CRD)
+// into p's context.
+func (p *PartitionCluster) ApplyManifest(manifest string) {
+	applyManifest(p, manifest)
+}
+
+// applyManifest writes manifest to a temp file and kubectl applies it in p's context.
+func applyManifest(p *PartitionCluster, manifest string) {
+	p.t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "peering-*.yaml")
+	require.NoError(p.t, err)
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(manifest)
+	require.NoError(p.t, err)
+	require.NoError(p.t, tmpFile.Close())
+
+	opts := p.Context.KubectlOptions(p.t)
+	k8s.RunKubectl(p.t, opts, "apply", "-f", tmpFile.Name())
+}
@@ -0,0 +1,50 @@
+package partitions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/consul-k8s/acceptance/framework/environment"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// moveSecret copies secretName from sourceContext's cluster into destContext's
+// cluster, stripping the fields that are specific to the source object (resource
+// version, UID, owner references) so it can be recreated as a standalone secret.
+func moveSecret(t *testing.T, sourceContext, destContext environment.TestContext, secretName string) {
+	t.Helper()
+
+	sourceNamespace := sourceContext.KubectlOptions(t).Namespace
+	destNamespace := destContext.KubectlOptions(t).Namespace
+
+	secret, err := sourceContext.KubernetesClient(t).CoreV1().Secrets(sourceNamespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("retrieving secret %s from source context: %s", secretName, err)
+	}
+
+	destSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: destNamespace,
+		},
+		Data: secret.Data,
+		Type: secret.Type,
+	}
+
+	destClient := destContext.KubernetesClient(t).CoreV1().Secrets(destNamespace)
+	if _, err := destClient.Get(context.Background(), secretName, metav1.GetOptions{}); err == nil {
+		logger.Logf(t, "secret %s already exists in destination context, deleting it first", secretName)
+		if err := destClient.Delete(context.Background(), secretName, metav1.DeleteOptions{}); err != nil {
+			t.Fatalf("deleting existing secret %s from destination context: %s", secretName, err)
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		t.Fatalf("checking for existing secret %s in destination context: %s", secretName, err)
+	}
+
+	if _, err := destClient.Create(context.Background(), destSecret, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating secret %s in destination context: %s", secretName, err)
+	}
+}
@@ -0,0 +1,94 @@
+package partitions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-k8s/acceptance/framework/config"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/partitions"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/require"
+)
+
+const denyIntentionFixturePath = "../fixtures/cases/crosspartition/deny-intention"
+
+// assertCrossPartitionTransparentProxyDNS exercises the DNS redirection and L7
+// authz paths introduced with admin partitions: with transparent proxy enabled, a
+// static-client in one partition should be able to resolve and reach a
+// static-server in the other partition via its `<svc>.service.<partition>.ap.consul`
+// name, and lose that ability once an intentions CRD denies it explicitly.
+func assertCrossPartitionTransparentProxyDNS(t *testing.T, cfg *config.TestConfig, defaultPartitionCluster, secondaryPartitionCluster *partitions.PartitionCluster, namespace string) {
+	t.Helper()
+
+	logger.Log(t, "deploying a transparent-proxy static-client into both partitions")
+	defaultPartitionCluster.DeployWorkload(namespace, staticClientTProxyFixturePath(cfg))
+	secondaryPartitionCluster.DeployWorkload(namespace, staticClientTProxyFixturePath(cfg))
+
+	defaultPartitionAddr := fmt.Sprintf("static-server.service.%s.ap.consul", defaultPartition)
+	secondaryPartitionAddr := fmt.Sprintf("static-server.service.%s.ap.consul", secondaryPartition)
+
+	logger.Logf(t, "asserting the %s partition can reach %s", secondaryPartition, defaultPartitionAddr)
+	assertCurl(t, secondaryPartitionCluster, namespace, defaultPartitionAddr, true)
+
+	logger.Logf(t, "asserting the %s partition can reach %s", defaultPartition, secondaryPartitionAddr)
+	assertCurl(t, defaultPartitionCluster, namespace, secondaryPartitionAddr, true)
+
+	logger.Logf(t, "denying cross-partition traffic from %s static-client into %s static-server", secondaryPartition, defaultPartition)
+	denyCrossPartitionIntention(t, defaultPartitionCluster, namespace, secondaryPartition)
+
+	logger.Logf(t, "asserting the %s partition can no longer reach %s", secondaryPartition, defaultPartitionAddr)
+	assertCurl(t, secondaryPartitionCluster, namespace, defaultPartitionAddr, false)
+}
+
+// denyCrossPartitionIntention applies a ServiceIntentions CRD in cluster's partition
+// that denies traffic from static-client in sourcePartition/namespace to static-server.
+func denyCrossPartitionIntention(t *testing.T, cluster *partitions.PartitionCluster, namespace, sourcePartition string) {
+	t.Helper()
+
+	manifest, err := os.ReadFile(filepath.Join(denyIntentionFixturePath, "intentions.yaml"))
+	require.NoError(t, err)
+
+	rendered := strings.ReplaceAll(string(manifest), "SOURCE_PARTITION", sourcePartition)
+	rendered = strings.ReplaceAll(rendered, "SOURCE_NAMESPACE", namespace)
+
+	tmpFile, err := os.CreateTemp("", "deny-intention-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(rendered)
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+
+	opts := cluster.Context.KubectlOptions(t)
+	k8s.RunKubectl(t, opts, "apply", "-n", namespace, "-f", tmpFile.Name())
+}
+
+// assertCurl execs into the static-client pod in cluster/namespace and retries
+// curling addr until it gets the expected result (success when expectSuccess,
+// a connection/authz failure otherwise).
+func assertCurl(t *testing.T, cluster *partitions.PartitionCluster, namespace, addr string, expectSuccess bool) {
+	t.Helper()
+
+	opts := cluster.Context.KubectlOptions(t)
+	podName, err := k8s.RunKubectlAndGetOutputE(t, opts, "get", "pod", "-n", namespace,
+		"-l", fmt.Sprintf("app=%s", staticClientName), "-o", "jsonpath={.items[0].metadata.name}")
+	require.NoError(t, err)
+	require.NotEmpty(t, podName)
+
+	retry.RunWith(&retry.Counter{Count: 10, Wait: 10 * time.Second}, t, func(r *retry.R) {
+		output, err := k8s.RunKubectlAndGetOutputE(t, opts, "exec", "-n", namespace, podName, "-c", staticClientName,
+			"--", "curl", "-s", "-f", fmt.Sprintf("http://%s", addr))
+
+		if expectSuccess {
+			require.NoError(r, err)
+			require.Contains(r, output, "hello world")
+		} else {
+			require.Error(r, err)
+		}
+	})
+}
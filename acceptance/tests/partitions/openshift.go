@@ -0,0 +1,59 @@
+package partitions
+
+import (
+	"fmt"
+	"testing"
+
+	terratestk8s "github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/config"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+)
+
+// serviceAccountsNeedingSCCs are the service accounts that need the anyuid and
+// privileged SCCs on OpenShift: the test fixtures below intentionally drop their
+// fixed UIDs so that they can run under OCP's restricted arbitrary-UID policy.
+// This only covers workloads deployed into the namespace createOpenShiftNamespace
+// is called with (staticServerNamespace); the Consul Helm release itself runs in
+// its own namespace and is out of scope here.
+var serviceAccountsNeedingSCCs = []string{staticServerName, staticClientName}
+
+// createOpenShiftNamespace creates namespace in the cluster targeted by options and,
+// when cfg.UseOpenShift is set, grants the anyuid and privileged SCCs to the
+// static-server and static-client service accounts so their pods can be admitted.
+// It is the OpenShift-aware replacement for a raw `kubectl create ns`.
+func createOpenShiftNamespace(t *testing.T, options *terratestk8s.KubectlOptions, cfg *config.TestConfig, namespace string) {
+	logger.Logf(t, "creating namespace %s", namespace)
+	k8s.RunKubectl(t, options, "create", "ns", namespace)
+
+	if !cfg.UseOpenShift {
+		return
+	}
+
+	for _, scc := range []string{"anyuid", "privileged"} {
+		for _, sa := range serviceAccountsNeedingSCCs {
+			logger.Logf(t, "granting %s SCC to %s/%s", scc, namespace, sa)
+			k8s.RunKubectl(t, options, "adm", "policy", "add-scc-to-user", scc,
+				fmt.Sprintf("system:serviceaccount:%s:%s", namespace, sa))
+		}
+	}
+}
+
+// staticServerFixturePath returns the kustomize overlay to deploy for the static-server
+// fixture. The OpenShift overlay drops the fixed UIDs baked into the base fixture so the
+// pods can run under whichever UID OCP's arbitrary-UID policy assigns.
+func staticServerFixturePath(cfg *config.TestConfig) string {
+	if cfg.UseOpenShift {
+		return "../fixtures/cases/openshift/static-server"
+	}
+	return "../fixtures/bases/static-server"
+}
+
+// staticClientTProxyFixturePath returns the kustomize overlay to deploy for a
+// transparent-proxy-enabled static-client fixture. See staticServerFixturePath.
+func staticClientTProxyFixturePath(cfg *config.TestConfig) string {
+	if cfg.UseOpenShift {
+		return "../fixtures/cases/openshift/static-client-tproxy"
+	}
+	return "../fixtures/cases/crosspartition/static-client-tproxy"
+}
@@ -0,0 +1,107 @@
+package partitions
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul-k8s/acceptance/framework/environment"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/helpers"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/k8s"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/logger"
+	"github.com/hashicorp/consul-k8s/acceptance/framework/partitions"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/stretchr/testify/require"
+)
+
+const peerName = "acceptor-to-dialer"
+
+const exportedServicesTemplate = `
+apiVersion: consul.hashicorp.com/v1alpha1
+kind: ExportedServices
+metadata:
+  name: default
+spec:
+  services:
+    - name: %s
+      consumers:
+        - peer: %s
+`
+
+// TestPartitions_Peering exercises admin partitions combined with cluster peering:
+// rather than joining the dialer cluster's agents to the acceptor cluster's servers
+// (as TestPartitions_Sync does via externalServers/client.join), it brings up two
+// fully independent Consul clusters -- each with its own servers and default admin
+// partition -- and peers them via the PeeringAcceptor/PeeringDialer CRDs. A service
+// exported from the acceptor's partition should then be reachable from the dialer's
+// partition, both in the catalog and over mesh connections.
+func TestPartitions_Peering(t *testing.T) {
+	env := suite.Environment()
+	cfg := suite.Config()
+
+	if !cfg.EnableEnterprise {
+		t.Skipf("skipping this test because -enable-enterprise is not set")
+	}
+	if !cfg.EnableTransparentProxy {
+		t.Skipf("skipping this test because -enable-transparent-proxy is not set")
+	}
+
+	acceptorContext := env.DefaultContext(t)
+	dialerContext := env.Context(t, environment.SecondaryContextName)
+
+	helmValues := map[string]string{
+		"global.adminPartitions.enabled": "true",
+		"global.enableConsulNamespaces":  "true",
+		"global.tls.enabled":             "true",
+		"global.peering.enabled":         "true",
+		"connectInject.enabled":          "true",
+		"dns.enabled":                    "true",
+		"dns.enableRedirection":          "true",
+	}
+
+	acceptorReleaseName := helpers.RandomName()
+	dialerReleaseName := helpers.RandomName()
+
+	logger.Log(t, "creating the acceptor partition")
+	acceptorCluster := partitions.NewDefaultPartition(t, acceptorContext, cfg, acceptorReleaseName, helmValues)
+
+	logger.Log(t, "creating the dialer partition")
+	dialerCluster := partitions.NewDefaultPartition(t, dialerContext, cfg, dialerReleaseName, helmValues)
+
+	logger.Log(t, "generating a peering token in the acceptor partition")
+	secretName := acceptorCluster.GeneratePeeringToken(peerName)
+
+	logger.Log(t, "establishing the peering from the dialer partition")
+	dialerCluster.EstablishPeering(acceptorCluster, peerName, secretName)
+
+	createOpenShiftNamespace(t, acceptorContext.KubectlOptions(t), cfg, staticServerNamespace)
+	helpers.Cleanup(t, cfg.NoCleanupOnFailure, func() {
+		k8s.RunKubectl(t, acceptorContext.KubectlOptions(t), "delete", "ns", staticServerNamespace)
+	})
+	createOpenShiftNamespace(t, dialerContext.KubectlOptions(t), cfg, staticServerNamespace)
+	helpers.Cleanup(t, cfg.NoCleanupOnFailure, func() {
+		k8s.RunKubectl(t, dialerContext.KubectlOptions(t), "delete", "ns", staticServerNamespace)
+	})
+
+	logger.Log(t, "deploying a static-server into the acceptor partition")
+	acceptorCluster.DeployWorkload(staticServerNamespace, staticServerFixturePath(cfg))
+
+	logger.Log(t, "deploying a transparent-proxy static-client into the dialer partition")
+	dialerCluster.DeployWorkload(staticServerNamespace, staticClientTProxyFixturePath(cfg))
+
+	logger.Log(t, "exporting static-server from the acceptor partition to the peer")
+	acceptorCluster.ApplyManifest(fmt.Sprintf(exportedServicesTemplate, staticServerName, peerName))
+
+	// An imported service keeps its original name in the catalog -- it's only
+	// visible to a query that explicitly asks for it via its peer name.
+	logger.Log(t, "checking that static-server is visible in the dialer partition's catalog via the peering")
+	dialerClient, dialerQueryOpts := dialerCluster.ConsulClientForPeer(peerName, "")
+	retry.RunWith(&retry.Counter{Count: 30, Wait: 10 * time.Second}, t, func(r *retry.R) {
+		services, _, err := dialerClient.Catalog().Services(dialerQueryOpts)
+		require.NoError(r, err)
+		require.Contains(r, services, staticServerName)
+	})
+
+	logger.Log(t, "checking that the dialer's static-client can reach static-server over the peering connection")
+	assertCurl(t, dialerCluster, staticServerNamespace, fmt.Sprintf("%s.virtual.%s.consul", staticServerName, peerName), true)
+}